@@ -0,0 +1,112 @@
+// Package audio wraps the game's embedded sound effects behind a small
+// player that mirrors how ebiten expects audio to be driven: one shared
+// audio.Context, decoded clips kept as raw PCM in memory, and a fresh
+// audio.Player spun up per play so overlapping sounds don't cut each
+// other off.
+package audio
+
+import (
+	"bytes"
+	_ "embed"
+	"io"
+	"log"
+
+	"github.com/hajimehoshi/ebiten/v2/audio"
+	"github.com/hajimehoshi/ebiten/v2/audio/wav"
+)
+
+const sampleRate = 44100
+
+//go:embed eat.wav
+var eatWav []byte
+
+//go:embed die.wav
+var dieWav []byte
+
+//go:embed powerup.wav
+var powerupWav []byte
+
+//go:embed spawn.wav
+var spawnWav []byte
+
+//go:embed gameover.wav
+var gameoverWav []byte
+
+// Per-clip volumes, tuned by ear the same way the carotidartillery
+// gunshotVolume/munchVolume constants were.
+const (
+	eatVolume      = 0.5
+	dieVolume      = 0.8
+	powerupVolume  = 0.6
+	spawnVolume    = 0.3
+	gameoverVolume = 0.8
+)
+
+// Eat, Die, Powerup, Spawn and GameOver are the clip names Play accepts.
+const (
+	Eat      = "eat"
+	Die      = "die"
+	Powerup  = "powerup"
+	Spawn    = "spawn"
+	GameOver = "gameover"
+)
+
+type clip struct {
+	pcm    []byte
+	volume float64
+}
+
+// Player owns the audio context and every decoded clip, and tracks
+// whether playback is currently muted.
+type Player struct {
+	ctx   *audio.Context
+	clips map[string]*clip
+	muted bool
+}
+
+// New decodes the embedded clips and returns a ready-to-use Player.
+func New() *Player {
+	ctx := audio.NewContext(sampleRate)
+	p := &Player{ctx: ctx, clips: map[string]*clip{}}
+	p.load(Eat, eatWav, eatVolume)
+	p.load(Die, dieWav, dieVolume)
+	p.load(Powerup, powerupWav, powerupVolume)
+	p.load(Spawn, spawnWav, spawnVolume)
+	p.load(GameOver, gameoverWav, gameoverVolume)
+	return p
+}
+
+func (p *Player) load(name string, raw []byte, volume float64) {
+	s, err := wav.DecodeWithoutResampling(bytes.NewReader(raw))
+	if err != nil {
+		log.Fatalf("failed to decode %s.wav: %v", name, err)
+	}
+	pcm, err := io.ReadAll(s)
+	if err != nil {
+		log.Fatalf("failed to read %s.wav: %v", name, err)
+	}
+	p.clips[name] = &clip{pcm: pcm, volume: volume}
+}
+
+// Play starts the named clip from the beginning on a fresh player, so
+// e.g. rapid-fire eats layer on top of each other instead of
+// restarting a shared one. It is a no-op while muted or for an unknown
+// name.
+func (p *Player) Play(name string) {
+	if p.muted {
+		return
+	}
+	c, ok := p.clips[name]
+	if !ok {
+		return
+	}
+	pl := p.ctx.NewPlayerFromBytes(c.pcm)
+	pl.SetVolume(c.volume)
+	pl.Play()
+}
+
+// ToggleMute flips the mute state and returns the new value.
+func (p *Player) ToggleMute() bool {
+	p.muted = !p.muted
+	return p.muted
+}
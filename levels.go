@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+)
+
+// Cell is one tile of a Level's grid.
+type Cell int
+
+const (
+	CellEmpty Cell = iota
+	CellWall
+	CellPortal
+)
+
+// Level is a playfield layout: a grid of cells plus the pair of
+// CellPortal tiles (if any) that teleport the snake's head into each
+// other.
+type Level struct {
+	Name    string
+	Grid    [][]Cell
+	Portals []Point
+	// Start is the snake's head tile, or {-1, -1} to default to the
+	// grid's center.
+	Start Point
+}
+
+// Width and Height report the level's tile dimensions.
+func (l *Level) Width() int  { return len(l.Grid[0]) }
+func (l *Level) Height() int { return len(l.Grid) }
+
+// IsBlocked reports whether p is outside the level or a wall tile.
+func (l *Level) IsBlocked(p Point) bool {
+	if p.Y < 0 || p.Y >= len(l.Grid) || p.X < 0 || p.X >= len(l.Grid[p.Y]) {
+		return true
+	}
+	return l.Grid[p.Y][p.X] == CellWall
+}
+
+// PortalExit reports the tile p teleports to, if p is itself a portal
+// tile with a partner elsewhere in the level.
+func (l *Level) PortalExit(p Point) (Point, bool) {
+	if l.IsBlocked(p) || l.Grid[p.Y][p.X] != CellPortal {
+		return Point{}, false
+	}
+	for _, q := range l.Portals {
+		if q != p {
+			return q, true
+		}
+	}
+	return Point{}, false
+}
+
+// parseLevel builds a Level from an ASCII map: '#' is a wall, 'O'/'o'
+// marks one end of a teleporting portal pair, 'S' marks the snake's
+// starting tile, and anything else (conventionally '.') is empty.
+func parseLevel(name string, lines []string) *Level {
+	l := &Level{Name: name, Start: Point{-1, -1}}
+	l.Grid = make([][]Cell, len(lines))
+	for y, line := range lines {
+		row := make([]Cell, len(line))
+		for x, ch := range line {
+			switch ch {
+			case '#':
+				row[x] = CellWall
+			case 'O', 'o':
+				row[x] = CellPortal
+				l.Portals = append(l.Portals, Point{X: x, Y: y})
+			case 'S':
+				l.Start = Point{X: x, Y: y}
+			}
+		}
+		l.Grid[y] = row
+	}
+	return l
+}
+
+// loadLevelFile reads an ASCII map from disk in the same format as
+// parseLevel.
+func loadLevelFile(path string) (*Level, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("%s: empty level file", path)
+	}
+	return parseLevel(path, lines), nil
+}
+
+// builtinLevels are offered from the title screen, in this order; a
+// level loaded via -level replaces them with a single-entry run.
+var builtinLevels = []*Level{
+	parseLevel("Open Field", []string{
+		".............",
+		".............",
+		".............",
+		".............",
+		".............",
+		".............",
+		".............",
+		".............",
+		".............",
+		".............",
+	}),
+	parseLevel("Cross", []string{
+		".............",
+		"......#......",
+		"......#......",
+		"......#......",
+		".#####..####.",
+		".............",
+		"......#......",
+		"......#......",
+		"......#......",
+		".............",
+	}),
+	parseLevel("Corridors", []string{
+		".............",
+		".............",
+		"############.",
+		".............",
+		".############",
+		".............",
+		"############.",
+		".............",
+		".............",
+		".............",
+	}),
+	parseLevel("Portals", []string{
+		".............",
+		".O...........",
+		".............",
+		".............",
+		".............",
+		".............",
+		".............",
+		".............",
+		"...........o.",
+		".............",
+	}),
+}
@@ -3,14 +3,23 @@ package main
 import (
 	"bytes"
 	_ "embed"
+	"flag"
 	"image/color"
 	"image/png"
 	"log"
+	"math"
 	"math/rand"
 	"time"
 
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+
+	"github.com/violinbg/snake-game-aimade/audio"
+	"github.com/violinbg/snake-game-aimade/scores"
 )
 
 //go:embed head.png
@@ -22,87 +31,317 @@ var bodyPng []byte
 //go:embed apple.png
 var applePng []byte
 
+//go:embed garlic.png
+var garlicPng []byte
+
+//go:embed holywater.png
+var holyWaterPng []byte
+
+//go:embed speed.png
+var speedPng []byte
+
+//go:embed shrink.png
+var shrinkPng []byte
+
+//go:embed creep.png
+var creepPng []byte
+
+//go:embed wall.png
+var wallPng []byte
+
 const (
 	screenWidth  = 320
 	screenHeight = 240
 	gridSize     = 24 // tile size in pixels
 	initialLen   = 3
+
+	numCreeps     = 3
+	seekDistance  = 5.0  // tiles within which a creep always homes in on the head
+	fleeChance    = 120  // 1-in-N chance per tick a creep homes in even outside seekDistance
+	creepMinSpeed = 0.03 // tiles per tick
+	creepMaxSpeed = 0.12 // tiles per tick
+
+	baseMoveInterval = 100 * time.Millisecond
+
+	garlicDuration    = 7 * time.Second
+	holyWaterDuration = 1 * time.Second
+	speedDuration     = 5 * time.Second
+	shrinkAmount      = 3
+
+	itemSpawnChance = 5 // 1-in-N spawns is a powerup instead of an apple
+
+	maxPendingDirs = 2 // queued turns per tick, enough for a quick corner
+	dirLogCapacity = 4096
+
+	applesPerLevel = 5 // apples eaten before advancing to the next level
 )
 
 type Point struct {
 	X, Y int
 }
 
-type Food struct {
+// gameState tracks which screen Update/Draw are currently driving.
+type gameState int
+
+const (
+	stateTitle gameState = iota
+	statePlaying
+	stateEnterName
+	stateGameOver
+)
+
+const nameLength = 3 // initials entered for a qualifying high score
+
+// dirChange is one entry in a game's direction-change log: the move
+// tick at which the snake's direction became Dir.
+type dirChange struct {
+	Tick int
+	Dir  Point
+}
+
+// ItemKind is the pickup type; KindApple is the plain score/growth
+// pickup the game always had, the rest are timed powerups.
+type ItemKind int
+
+const (
+	KindApple ItemKind = iota
+	KindGarlic
+	KindHolyWater
+	KindSpeed
+	KindShrink
+)
+
+// Item is a pickup on the grid. It replaces the old Food type now that
+// there's more than one kind of thing to pick up.
+type Item struct {
+	Kind     ItemKind
 	Pos      Point
 	Spawned  time.Time
 	Lifetime time.Duration
 }
 
+// CreepKind distinguishes creep behaviors; only the wandering/seeking
+// enemy exists for now, but powerup work will add more.
+type CreepKind int
+
+const (
+	CreepWanderer CreepKind = iota
+)
+
+// Creep is an AI-controlled enemy that shares the grid with the snake.
+// Pos and Vel are kept in tile units (not pixels) with sub-tile
+// precision so movement reads smoothly despite the snake's own
+// fixed-grid stepping.
+type Creep struct {
+	Pos  [2]float64
+	Vel  [2]float64
+	Kind CreepKind
+}
+
 type Game struct {
+	state        gameState
 	snake        []Point
 	dir          Point
-	foods        []Food
+	items        []Item
+	creeps       []*Creep
 	grow         bool
-	gameOver     bool
 	lastMove     time.Time
+	moveInterval time.Duration
 	score        int
 	lives        int
-	nextFoodTime time.Time
+	nextItemTime time.Time
+	paused       bool
+	// effects maps an active powerup to the time it wears off; a kind
+	// absent from the map (or with a past timestamp) is inactive.
+	effects map[ItemKind]time.Time
+
+	// levels is the sequence of layouts a run cycles through, advancing
+	// every applesPerLevel apples eaten; a custom -level run has just
+	// one entry and never advances. selectedLevel is the title-screen
+	// choice of starting level; runStartLevel/lastLevelIndex remember it
+	// the same way seed/lastSeed do, so a replay begins on the right one.
+	levels         []*Level
+	levelIndex     int
+	levelApples    int
+	selectedLevel  int
+	runStartLevel  int
+	lastLevelIndex int
+
+	// highScores is the persisted table; printer renders its Score
+	// column with locale-aware thousands separators.
+	highScores        []scores.Entry
+	leaderboardScroll int
+	printer           *message.Printer
+
+	// nameInput/pendingEntry back the stateEnterName prompt shown when
+	// a run's score qualifies for the high score table.
+	nameInput    []rune
+	pendingEntry scores.Entry
+
+	// pendingDirs queues up to maxPendingDirs direction changes so quick
+	// corner turns aren't dropped between move ticks.
+	pendingDirs []Point
+
+	// rng drives every random choice in a run (creep wander/flee rolls,
+	// item kind/placement) so a run can be replayed deterministically
+	// from its seed and recorded direction log.
+	rng      *rand.Rand
+	seed     int64
+	moveTick int
+	dirLog   []dirChange
+	// lastSeed/lastDirLog hold the most recently finished run so KeyR
+	// can replay it after game over.
+	lastSeed   int64
+	lastDirLog []dirChange
+	replaying  bool
+	replayLog  []dirChange
+	replayPos  int
+
 	// Images
-	headImg *ebiten.Image
-	bodyImg *ebiten.Image
-	foodImg *ebiten.Image
+	headImg  *ebiten.Image
+	bodyImg  *ebiten.Image
+	creepImg *ebiten.Image
+	wallImg  *ebiten.Image
+	itemImgs map[ItemKind]*ebiten.Image
+
+	sound *audio.Player
+}
+
+// playSound is a no-op while g.sound is nil, so callers don't need to
+// check g.sound themselves.
+func (g *Game) playSound(name string) {
+	if g.sound != nil {
+		g.sound.Play(name)
+	}
+}
+
+// effectActive reports whether the given powerup is currently in effect.
+func (g *Game) effectActive(k ItemKind) bool {
+	return time.Now().Before(g.effects[k])
+}
+
+// currentLevel is the layout the run is currently playing.
+func (g *Game) currentLevel() *Level {
+	return g.levels[g.levelIndex]
+}
+
+// headFlashing reports whether the snake head should show the
+// active-effect tint right now: on for any active effect, blinking
+// every 100ms once it's within its last second, same pattern used for
+// expiring food.
+func (g *Game) headFlashing() bool {
+	now := time.Now()
+	for _, until := range g.effects {
+		if !now.Before(until) {
+			continue
+		}
+		remaining := until.Sub(now)
+		if remaining >= time.Second {
+			return true
+		}
+		if (now.UnixMilli()/100)%2 == 0 {
+			return true
+		}
+	}
+	return false
 }
 
 func (g *Game) Update() error {
-	if g.gameOver {
-		if ebiten.IsKeyPressed(ebiten.KeySpace) {
-			g.lives = 3
-			g.score = 0
-			g.init()
+	switch g.state {
+	case stateTitle:
+		return g.updateTitle()
+	case stateEnterName:
+		return g.updateEnterName()
+	case stateGameOver:
+		if inpututil.IsKeyJustPressed(ebiten.KeySpace) {
+			g.newGame()
+		} else if inpututil.IsKeyJustPressed(ebiten.KeyR) {
+			g.startReplay()
 		}
 		return nil
 	}
 
-	// Direction input
-	if ebiten.IsKeyPressed(ebiten.KeyArrowUp) && g.dir.Y != 1 {
-		g.dir = Point{0, -1}
-	} else if ebiten.IsKeyPressed(ebiten.KeyArrowDown) && g.dir.Y != -1 {
-		g.dir = Point{0, 1}
-	} else if ebiten.IsKeyPressed(ebiten.KeyArrowLeft) && g.dir.X != 1 {
-		g.dir = Point{-1, 0}
-	} else if ebiten.IsKeyPressed(ebiten.KeyArrowRight) && g.dir.X != -1 {
-		g.dir = Point{1, 0}
+	if inpututil.IsKeyJustPressed(ebiten.KeyP) {
+		g.paused = !g.paused
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyM) && g.sound != nil {
+		g.sound.ToggleMute()
+	}
+	if g.paused {
+		return nil
 	}
 
-	// Move snake every 100ms
-	if time.Since(g.lastMove) < 100*time.Millisecond {
+	// Direction input: queue up to maxPendingDirs turns so a quick
+	// corner (e.g. up then immediately left) isn't dropped because both
+	// keys land within the same move tick. Replays ignore the keyboard
+	// and instead pull recorded turns at the matching tick below.
+	if !g.replaying {
+		switch {
+		case inpututil.IsKeyJustPressed(ebiten.KeyArrowUp):
+			g.queueDir(Point{0, -1})
+		case inpututil.IsKeyJustPressed(ebiten.KeyArrowDown):
+			g.queueDir(Point{0, 1})
+		case inpututil.IsKeyJustPressed(ebiten.KeyArrowLeft):
+			g.queueDir(Point{-1, 0})
+		case inpututil.IsKeyJustPressed(ebiten.KeyArrowRight):
+			g.queueDir(Point{1, 0})
+		}
+	}
+
+	// Move snake every moveInterval (halved while the speed powerup is active)
+	interval := g.moveInterval
+	if g.effectActive(KindSpeed) {
+		interval /= 2
+	}
+	if time.Since(g.lastMove) < interval {
 		return nil
 	}
 	g.lastMove = time.Now()
 
+	if g.replaying {
+		for g.replayPos < len(g.replayLog) && g.replayLog[g.replayPos].Tick == g.moveTick {
+			g.dir = g.replayLog[g.replayPos].Dir
+			g.replayPos++
+		}
+	} else if len(g.pendingDirs) > 0 {
+		next := g.pendingDirs[0]
+		g.pendingDirs = g.pendingDirs[1:]
+		if next != g.dir {
+			g.dir = next
+			g.dirLog = append(g.dirLog, dirChange{Tick: g.moveTick, Dir: g.dir})
+			if len(g.dirLog) > dirLogCapacity {
+				g.dirLog = g.dirLog[1:]
+			}
+		}
+	}
+	g.moveTick++
+
 	head := g.snake[0]
 	newHead := Point{head.X + g.dir.X, head.Y + g.dir.Y}
+	if exit, ok := g.currentLevel().PortalExit(newHead); ok {
+		newHead = exit
+	}
 
-	// Check collision with walls
-	if newHead.X < 0 || newHead.Y < 0 || newHead.X >= screenWidth/gridSize || newHead.Y >= screenHeight/gridSize {
+	// Check collision with walls (including the level border)
+	if g.currentLevel().IsBlocked(newHead) {
+		g.playSound(audio.Die)
 		g.lives--
 		if g.lives > 0 {
 			g.init()
 		} else {
-			g.gameOver = true
+			g.endGame()
 		}
 		return nil
 	}
 	// Check collision with self
 	for _, s := range g.snake {
 		if s == newHead {
+			g.playSound(audio.Die)
 			g.lives--
 			if g.lives > 0 {
 				g.init()
 			} else {
-				g.gameOver = true
+				g.endGame()
 			}
 			return nil
 		}
@@ -110,19 +349,43 @@ func (g *Game) Update() error {
 
 	g.snake = append([]Point{newHead}, g.snake...)
 
-	// Check collision with any food
-	ateFood := -1
-	for i, food := range g.foods {
-		if newHead == food.Pos {
-			ateFood = i
+	g.updateCreeps(newHead)
+	touchedCreep := -1
+	for i, c := range g.creeps {
+		cx, cy := int(math.Round(c.Pos[0])), int(math.Round(c.Pos[1]))
+		if cx == newHead.X && cy == newHead.Y {
+			touchedCreep = i
 			break
 		}
 	}
-	if ateFood != -1 {
-		g.grow = true
-		g.score += 10
-		// Remove the eaten food
-		g.foods = append(g.foods[:ateFood], g.foods[ateFood+1:]...)
+	if touchedCreep != -1 {
+		if g.effectActive(KindHolyWater) {
+			// Holy water neutralizes the creep it touches rather than
+			// just granting invulnerability against it.
+			g.creeps = append(g.creeps[:touchedCreep], g.creeps[touchedCreep+1:]...)
+		} else {
+			g.playSound(audio.Die)
+			g.lives--
+			if g.lives > 0 {
+				g.init()
+			} else {
+				g.endGame()
+			}
+			return nil
+		}
+	}
+
+	// Check collision with any item
+	ateItem := -1
+	for i, item := range g.items {
+		if newHead == item.Pos {
+			ateItem = i
+			break
+		}
+	}
+	if ateItem != -1 {
+		g.applyItem(g.items[ateItem].Kind)
+		g.items = append(g.items[:ateItem], g.items[ateItem+1:]...)
 	}
 	if !g.grow {
 		g.snake = g.snake[:len(g.snake)-1]
@@ -130,33 +393,169 @@ func (g *Game) Update() error {
 		g.grow = false
 	}
 
-	// Remove expired food
+	// Remove expired items
 	now := time.Now()
-	filtered := g.foods[:0]
-	for _, food := range g.foods {
-		if now.Sub(food.Spawned) < food.Lifetime {
-			filtered = append(filtered, food)
+	filtered := g.items[:0]
+	for _, item := range g.items {
+		if now.Sub(item.Spawned) < item.Lifetime {
+			filtered = append(filtered, item)
 		}
 	}
-	g.foods = filtered
+	g.items = filtered
 
-	// Food spawn logic: spawn one at a time with delay, but if less than 4, spawn immediately
-	if len(g.foods) < 4 {
-		if g.nextFoodTime.IsZero() || now.After(g.nextFoodTime) {
-			g.spawnFood()
-			if len(g.foods) < 4 {
-				g.nextFoodTime = now // spawn next immediately
+	// Item spawn logic: spawn one at a time with delay, but if less than 4, spawn immediately
+	if len(g.items) < 4 {
+		if g.nextItemTime.IsZero() || now.After(g.nextItemTime) {
+			g.spawnItem()
+			if len(g.items) < 4 {
+				g.nextItemTime = now // spawn next immediately
 			} else {
-				g.nextFoodTime = now.Add(1 * time.Second) // delay for next spawn
+				g.nextItemTime = now.Add(1 * time.Second) // delay for next spawn
 			}
 		}
 	}
 
+	// Advance to the next level once enough apples have been eaten, after
+	// this tick's own grow/expire/spawn bookkeeping above so the level's
+	// init() reset isn't immediately undone by it.
+	if len(g.levels) > 1 && g.levelApples >= applesPerLevel {
+		g.advanceLevel()
+	}
+
 	return nil
 }
 
+// updateTitle drives the title/high-scores screen shown before the
+// first game; after a game over, Space starts the next run directly.
+func (g *Game) updateTitle() error {
+	if inpututil.IsKeyJustPressed(ebiten.KeyArrowUp) && g.leaderboardScroll > 0 {
+		g.leaderboardScroll--
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyArrowDown) && g.leaderboardScroll < len(g.highScores)-1 {
+		g.leaderboardScroll++
+	}
+	if len(g.levels) > 1 {
+		if inpututil.IsKeyJustPressed(ebiten.KeyArrowLeft) {
+			g.selectedLevel = (g.selectedLevel - 1 + len(g.levels)) % len(g.levels)
+		}
+		if inpututil.IsKeyJustPressed(ebiten.KeyArrowRight) {
+			g.selectedLevel = (g.selectedLevel + 1) % len(g.levels)
+		}
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeySpace) {
+		g.newGame()
+	}
+	return nil
+}
+
+// updateEnterName reads up to nameLength letters for a qualifying
+// score, then saves the table and moves on to the game-over screen.
+func (g *Game) updateEnterName() error {
+	for k := ebiten.KeyA; k <= ebiten.KeyZ; k++ {
+		if inpututil.IsKeyJustPressed(k) && len(g.nameInput) < nameLength {
+			g.nameInput = append(g.nameInput, 'A'+rune(k-ebiten.KeyA))
+		}
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyBackspace) && len(g.nameInput) > 0 {
+		g.nameInput = g.nameInput[:len(g.nameInput)-1]
+	}
+	if len(g.nameInput) == nameLength {
+		g.pendingEntry.Name = string(g.nameInput)
+		g.highScores = scores.Add(g.highScores, g.pendingEntry)
+		if err := scores.Save(g.highScores); err != nil {
+			log.Printf("failed to save high scores: %v", err)
+		}
+		g.state = stateGameOver
+	}
+	return nil
+}
+
+// applyItem resolves the effect of picking up one item.
+func (g *Game) applyItem(kind ItemKind) {
+	now := time.Now()
+	switch kind {
+	case KindApple:
+		g.grow = true
+		g.score += 10
+		g.playSound(audio.Eat)
+		g.levelApples++
+	case KindGarlic:
+		g.effects[KindGarlic] = now.Add(garlicDuration)
+		g.playSound(audio.Powerup)
+	case KindHolyWater:
+		g.effects[KindHolyWater] = now.Add(holyWaterDuration)
+		g.playSound(audio.Powerup)
+	case KindSpeed:
+		g.effects[KindSpeed] = now.Add(speedDuration)
+		g.playSound(audio.Powerup)
+	case KindShrink:
+		// Every non-growing pickup (this one included) loses one more
+		// segment to the unconditional trailing trim in Update, so leave
+		// at least 2 here or that trim would empty the slice.
+		n := len(g.snake) - shrinkAmount
+		if n < 2 {
+			n = 2
+		}
+		g.snake = g.snake[:n]
+		g.playSound(audio.Powerup)
+	}
+}
+
 func (g *Game) Draw(screen *ebiten.Image) {
 	screen.Fill(color.RGBA{0, 0, 0, 0xff})
+	if g.state == stateTitle {
+		g.drawTitle(screen)
+		return
+	}
+	g.drawPlayfield(screen)
+	switch g.state {
+	case stateGameOver:
+		ebitenutil.DebugPrintAt(screen, "Game Over! Press Space to restart, R to replay.", 24, screenHeight/2-8)
+	case stateEnterName:
+		ebitenutil.DebugPrintAt(screen, "New high score! Initials: "+string(g.nameInput), 4, screenHeight/2-8)
+	default:
+		if g.paused {
+			ebitenutil.DebugPrintAt(screen, "Paused", screenWidth/2-20, screenHeight/2-8)
+		}
+	}
+}
+
+// drawTitle renders the title/high-scores screen shown before play.
+func (g *Game) drawTitle(screen *ebiten.Image) {
+	ebitenutil.DebugPrintAt(screen, "SNAKE", screenWidth/2-20, 16)
+	ebitenutil.DebugPrintAt(screen, "Press SPACE to start", screenWidth/2-76, 32)
+	levelLine := "Level: " + g.levels[g.selectedLevel].Name
+	if len(g.levels) > 1 {
+		levelLine += " (left/right to change)"
+	}
+	ebitenutil.DebugPrintAt(screen, levelLine, 4, 44)
+	ebitenutil.DebugPrintAt(screen, "High Scores (arrows to scroll):", 4, 56)
+	const rows = 8
+	for i := 0; i < rows && g.leaderboardScroll+i < len(g.highScores); i++ {
+		e := g.highScores[g.leaderboardScroll+i]
+		line := g.printer.Sprintf("%2d. %-3s %d  (%s, len %d)", g.leaderboardScroll+i+1, e.Name, e.Score, e.Date.Format("2006-01-02"), e.Length)
+		ebitenutil.DebugPrintAt(screen, line, 4, 72+i*12)
+	}
+}
+
+func (g *Game) drawPlayfield(screen *ebiten.Image) {
+	// Draw the level's walls and portals
+	lvl := g.currentLevel()
+	for y, row := range lvl.Grid {
+		for x, c := range row {
+			switch c {
+			case CellWall:
+				if g.wallImg != nil {
+					op := &ebiten.DrawImageOptions{}
+					op.GeoM.Translate(float64(x*gridSize), float64(y*gridSize))
+					screen.DrawImage(g.wallImg, op)
+				}
+			case CellPortal:
+				ebitenutil.DrawRect(screen, float64(x*gridSize), float64(y*gridSize), gridSize, gridSize, color.RGBA{0x40, 0x40, 0xff, 0x80})
+			}
+		}
+	}
+
 	// Draw snake
 	for i, s := range g.snake {
 		op := &ebiten.DrawImageOptions{}
@@ -177,6 +576,9 @@ func (g *Game) Draw(screen *ebiten.Image) {
 			}
 			op.GeoM.Translate(cx+float64(s.X*gridSize), cy+float64(s.Y*gridSize))
 			if g.headImg != nil {
+				if g.headFlashing() {
+					op.ColorM.Scale(1, 0.4, 0.4, 1)
+				}
 				screen.DrawImage(g.headImg, op)
 			}
 		} else {
@@ -225,28 +627,33 @@ func (g *Game) Draw(screen *ebiten.Image) {
 			}
 		}
 	}
-	// Draw food items
+	// Draw items
 	now := time.Now()
-	for _, food := range g.foods {
-		elapsed := now.Sub(food.Spawned)
+	for _, item := range g.items {
+		elapsed := now.Sub(item.Spawned)
 		// Flashing effect in last 1s
 		visible := true
-		if food.Lifetime-elapsed < time.Second {
+		if item.Lifetime-elapsed < time.Second {
 			// Flash every 100ms
 			visible = (elapsed.Milliseconds()/100)%2 == 0
 		}
-		if visible && g.foodImg != nil {
+		if img := g.itemImgs[item.Kind]; visible && img != nil {
 			op := &ebiten.DrawImageOptions{}
-			op.GeoM.Translate(float64(food.Pos.X*gridSize), float64(food.Pos.Y*gridSize))
-			screen.DrawImage(g.foodImg, op)
+			op.GeoM.Translate(float64(item.Pos.X*gridSize), float64(item.Pos.Y*gridSize))
+			screen.DrawImage(img, op)
 		}
 	}
-	// Draw score and lives
-	ebitenutil.DebugPrintAt(screen, "Score: "+itoa(g.score)+"  Lives: "+itoa(g.lives), 4, 4)
-	// Game over message
-	if g.gameOver {
-		ebitenutil.DebugPrintAt(screen, "Game Over! Press Space to restart.", 60, screenHeight/2-8)
+	// Draw creeps
+	for _, c := range g.creeps {
+		if g.creepImg == nil {
+			continue
+		}
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(c.Pos[0]*gridSize, c.Pos[1]*gridSize)
+		screen.DrawImage(g.creepImg, op)
 	}
+	// Draw score and lives
+	ebitenutil.DebugPrintAt(screen, g.printer.Sprintf("Score: %d  Lives: %d", g.score, g.lives), 4, 4)
 }
 
 func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
@@ -254,24 +661,219 @@ func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
 }
 
 func (g *Game) init() {
+	lvl := g.currentLevel()
+	start := lvl.Start
+	if start.X < 0 {
+		start = Point{X: lvl.Width() / 2, Y: lvl.Height() / 2}
+	}
 	g.snake = make([]Point, initialLen)
 	for i := 0; i < initialLen; i++ {
-		g.snake[i] = Point{X: screenWidth/gridSize/2 - i, Y: screenHeight / gridSize / 2}
+		g.snake[i] = Point{X: start.X - i, Y: start.Y}
 	}
 	g.dir = Point{1, 0}
 	g.grow = false
-	g.gameOver = false
-	g.foods = nil
+	g.state = statePlaying
+	g.paused = false
+	g.items = nil
+	g.pendingDirs = nil
 	g.lastMove = time.Now()
-	g.nextFoodTime = time.Now()
+	g.moveInterval = baseMoveInterval
+	g.nextItemTime = time.Now()
+	g.effects = make(map[ItemKind]time.Time)
+	g.spawnCreeps()
+}
+
+// queueDir appends a direction change to the pending queue, dropping
+// redundant turns and 180-degree reversals the same way the old
+// per-tick polling check did.
+func (g *Game) queueDir(d Point) {
+	if len(g.pendingDirs) >= maxPendingDirs {
+		return
+	}
+	last := g.dir
+	if n := len(g.pendingDirs); n > 0 {
+		last = g.pendingDirs[n-1]
+	}
+	if d == last || (d.X == -last.X && d.Y == -last.Y) {
+		return
+	}
+	g.pendingDirs = append(g.pendingDirs, d)
+}
+
+// newGame starts a brand new run: fresh seed, fresh direction log,
+// lives and score reset, starting at the title screen's selected level.
+func (g *Game) newGame() {
+	g.seed = time.Now().UnixNano()
+	g.rng = rand.New(rand.NewSource(g.seed))
+	g.moveTick = 0
+	g.dirLog = nil
+	g.replaying = false
+	g.lives = 3
+	g.score = 0
+	g.levelIndex = g.selectedLevel
+	g.levelApples = 0
+	g.runStartLevel = g.levelIndex
+	g.init()
+}
+
+// advanceLevel moves to the next built-in level (wrapping around) once
+// applesPerLevel have been eaten, carrying over score and lives since
+// init only resets the per-life state.
+func (g *Game) advanceLevel() {
+	g.levelIndex = (g.levelIndex + 1) % len(g.levels)
+	g.levelApples = 0
+	g.init()
 }
 
-func (g *Game) spawnFood() {
-	// Place food at a random position not occupied by the snake or other food
+// startReplay re-seeds the RNG from the previous run and feeds back its
+// recorded direction log instead of reading the keyboard, reproducing
+// that run deterministically.
+func (g *Game) startReplay() {
+	if g.lastDirLog == nil {
+		return
+	}
+	g.seed = g.lastSeed
+	g.rng = rand.New(rand.NewSource(g.seed))
+	g.moveTick = 0
+	g.dirLog = nil
+	g.replaying = true
+	g.replayLog = g.lastDirLog
+	g.replayPos = 0
+	g.lives = 3
+	g.score = 0
+	g.levelIndex = g.lastLevelIndex
+	g.levelApples = 0
+	g.runStartLevel = g.levelIndex
+	g.init()
+}
+
+// endGame marks the run over, stashes its seed and direction log so
+// KeyR can replay it, and routes through the initials prompt first if
+// the score just earned a spot on the high score table.
+func (g *Game) endGame() {
+	g.lastSeed = g.seed
+	// A replay re-stashes the log it played back, and the copy is never
+	// nil, so a run with no turns can be replayed too.
+	played := g.dirLog
+	if g.replaying {
+		played = g.replayLog
+	}
+	g.lastDirLog = append([]dirChange{}, played...)
+	g.lastLevelIndex = g.runStartLevel
+	g.playSound(audio.GameOver)
+	if scores.Qualifies(g.highScores, g.score) {
+		g.pendingEntry = scores.Entry{Score: g.score, Date: time.Now(), Length: len(g.snake)}
+		g.nameInput = nil
+		g.state = stateEnterName
+	} else {
+		g.state = stateGameOver
+	}
+}
+
+// spawnCreeps (re)populates the creep roster at random tile positions.
+func (g *Game) spawnCreeps() {
+	lvl := g.currentLevel()
+	g.creeps = make([]*Creep, numCreeps)
+	for i := range g.creeps {
+		var x, y int
+		for {
+			x = g.rng.Intn(lvl.Width())
+			y = g.rng.Intn(lvl.Height())
+			if !lvl.IsBlocked(Point{X: x, Y: y}) {
+				break
+			}
+		}
+		g.creeps[i] = &Creep{
+			Pos:  [2]float64{float64(x), float64(y)},
+			Kind: CreepWanderer,
+		}
+	}
+}
+
+// updateCreeps runs one AI tick per creep: seek the head when it's
+// close (or a random trigger fires), otherwise wander; then advance
+// position and clamp to the grid. While garlic is active the seek
+// vector is inverted so creeps flee instead. A step that would land on
+// a wall tile is dropped for that tick, so creeps can't drift through
+// maze walls.
+func (g *Game) updateCreeps(head Point) {
+	fleeing := g.effectActive(KindGarlic)
+	lvl := g.currentLevel()
+	maxX := float64(lvl.Width() - 1)
+	maxY := float64(lvl.Height() - 1)
+	for _, c := range g.creeps {
+		dx := float64(head.X) - c.Pos[0]
+		dy := float64(head.Y) - c.Pos[1]
+		dist := math.Hypot(dx, dy)
+		if dist <= seekDistance || g.rng.Intn(fleeChance) == 0 {
+			a := math.Atan2(dy, dx)
+			vx, vy := math.Cos(a), math.Sin(a)
+			if fleeing {
+				vx, vy = -vx, -vy
+			}
+			c.Vel = rescaleCreepSpeed(vx, vy)
+		} else {
+			angle := g.rng.Float64() * 2 * math.Pi
+			c.Vel = rescaleCreepSpeed(math.Cos(angle), math.Sin(angle))
+		}
+		newX := c.Pos[0] + c.Vel[0]
+		newY := c.Pos[1] + c.Vel[1]
+		if newX < 0 {
+			newX = 0
+		} else if newX > maxX {
+			newX = maxX
+		}
+		if newY < 0 {
+			newY = 0
+		} else if newY > maxY {
+			newY = maxY
+		}
+		if lvl.IsBlocked(Point{X: int(math.Round(newX)), Y: int(math.Round(newY))}) {
+			continue
+		}
+		c.Pos[0], c.Pos[1] = newX, newY
+	}
+}
+
+// rescaleCreepSpeed scales (vx, vy) into [creepMinSpeed, creepMaxSpeed]
+// by repeatedly nudging its magnitude 10% at a time, same trick the
+// carotidartillery enemy AI uses instead of a direct normalize-and-scale.
+func rescaleCreepSpeed(vx, vy float64) [2]float64 {
+	speed := math.Hypot(vx, vy)
+	if speed == 0 {
+		return [2]float64{0, 0}
+	}
+	for speed < creepMinSpeed {
+		vx *= 1.1
+		vy *= 1.1
+		speed = math.Hypot(vx, vy)
+	}
+	for speed > creepMaxSpeed {
+		vx *= 0.9
+		vy *= 0.9
+		speed = math.Hypot(vx, vy)
+	}
+	return [2]float64{vx, vy}
+}
+
+// powerupKinds lists the non-apple kinds spawnItem may pick.
+var powerupKinds = []ItemKind{KindGarlic, KindHolyWater, KindSpeed, KindShrink}
+
+func (g *Game) spawnItem() {
+	kind := KindApple
+	if g.rng.Intn(itemSpawnChance) == 0 {
+		kind = powerupKinds[g.rng.Intn(len(powerupKinds))]
+	}
+	// Place the item at a random position not occupied by a wall, the
+	// snake or another item.
+	lvl := g.currentLevel()
 	for {
-		fx := rand.Intn(screenWidth / gridSize)
-		fy := rand.Intn(screenHeight / gridSize)
+		fx := g.rng.Intn(lvl.Width())
+		fy := g.rng.Intn(lvl.Height())
 		pos := Point{fx, fy}
+		if lvl.IsBlocked(pos) || lvl.Grid[fy][fx] == CellPortal {
+			continue
+		}
 		overlap := false
 		for _, s := range g.snake {
 			if s == pos {
@@ -279,47 +881,25 @@ func (g *Game) spawnFood() {
 				break
 			}
 		}
-		for _, f := range g.foods {
-			if f.Pos == pos {
+		for _, it := range g.items {
+			if it.Pos == pos {
 				overlap = true
 				break
 			}
 		}
 		if !overlap {
-			g.foods = append(g.foods, Food{
+			g.items = append(g.items, Item{
+				Kind:     kind,
 				Pos:      pos,
 				Spawned:  time.Now(),
 				Lifetime: 4 * time.Second,
 			})
+			g.playSound(audio.Spawn)
 			return
 		}
 	}
 }
 
-// Helper function for int to string (no strconv needed for this simple case)
-func itoa(i int) string {
-	if i == 0 {
-		return "0"
-	}
-	neg := false
-	if i < 0 {
-		neg = true
-		i = -i
-	}
-	var b [20]byte
-	bp := len(b)
-	for i > 0 {
-		bp--
-		b[bp] = byte('0' + i%10)
-		i /= 10
-	}
-	if neg {
-		bp--
-		b[bp] = '-'
-	}
-	return string(b[bp:])
-}
-
 func loadImageFromBytes(data []byte, name string) *ebiten.Image {
 	img, err := png.Decode(bytes.NewReader(data))
 	if err != nil {
@@ -330,14 +910,40 @@ func loadImageFromBytes(data []byte, name string) *ebiten.Image {
 }
 
 func main() {
-	game := &Game{}
-	game.lives = 3
-	game.score = 0
+	levelPath := flag.String("level", "", "path to a custom ASCII level map (# wall, . empty, O/o portal pair, S start)")
+	flag.Parse()
+
+	levels := builtinLevels
+	if *levelPath != "" {
+		lvl, err := loadLevelFile(*levelPath)
+		if err != nil {
+			log.Fatalf("failed to load level %s: %v", *levelPath, err)
+		}
+		levels = []*Level{lvl}
+	}
+
+	game := &Game{levels: levels}
 	// Load images from embedded data
 	game.headImg = loadImageFromBytes(headPng, "head.png")
 	game.bodyImg = loadImageFromBytes(bodyPng, "body.png")
-	game.foodImg = loadImageFromBytes(applePng, "apple.png")
-	game.init()
+	game.creepImg = loadImageFromBytes(creepPng, "creep.png")
+	game.wallImg = loadImageFromBytes(wallPng, "wall.png")
+	game.itemImgs = map[ItemKind]*ebiten.Image{
+		KindApple:     loadImageFromBytes(applePng, "apple.png"),
+		KindGarlic:    loadImageFromBytes(garlicPng, "garlic.png"),
+		KindHolyWater: loadImageFromBytes(holyWaterPng, "holywater.png"),
+		KindSpeed:     loadImageFromBytes(speedPng, "speed.png"),
+		KindShrink:    loadImageFromBytes(shrinkPng, "shrink.png"),
+	}
+	game.sound = audio.New()
+	game.printer = message.NewPrinter(language.English)
+	highScores, err := scores.Load()
+	if err != nil {
+		log.Printf("failed to load high scores: %v", err)
+		highScores = []scores.Entry{}
+	}
+	game.highScores = highScores
+	game.state = stateTitle
 	ebiten.SetWindowTitle("Snake Game")
 	if err := ebiten.RunGame(game); err != nil {
 		log.Fatal(err)
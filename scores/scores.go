@@ -0,0 +1,87 @@
+// Package scores persists the game's top-10 high score table as JSON
+// under the user's config directory.
+package scores
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// MaxEntries is the number of entries kept in the table.
+const MaxEntries = 10
+
+// Entry is one row of the high score table.
+type Entry struct {
+	Name   string
+	Score  int
+	Date   time.Time
+	Length int
+}
+
+func filePath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "snake-game-aimade", "scores.json"), nil
+}
+
+// Load reads the high score table, returning an empty (not nil) slice
+// if no table has been saved yet.
+func Load() ([]Entry, error) {
+	p, err := filePath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return []Entry{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entries []Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Save writes the high score table, creating its directory if needed.
+func Save(entries []Entry) error {
+	p, err := filePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(p, data, 0o644)
+}
+
+// Qualifies reports whether score would earn a spot in the table.
+func Qualifies(entries []Entry, score int) bool {
+	if len(entries) < MaxEntries {
+		return true
+	}
+	return score > entries[len(entries)-1].Score
+}
+
+// Add inserts e into entries, keeping the result sorted by descending
+// score and capped at MaxEntries.
+func Add(entries []Entry, e Entry) []Entry {
+	entries = append(entries, e)
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Score > entries[j].Score })
+	if len(entries) > MaxEntries {
+		entries = entries[:MaxEntries]
+	}
+	return entries
+}